@@ -0,0 +1,104 @@
+// Package config loads the monitor's runtime configuration (monitored
+// chats, user filters, detector selection) from a YAML file and keeps it
+// reloadable without a restart, via a Store.
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChatConfig is a per-chat override of the global detector/stream settings.
+type ChatConfig struct {
+	ChatID  int64 `yaml:"chat_id"`
+	Enabled bool  `yaml:"enabled"`
+
+	// EnabledDetectors overrides Config.Detectors.Enabled for this chat. A
+	// nil slice means "use the global list".
+	EnabledDetectors []string `yaml:"enabled_detectors"`
+
+	// MinConfidence is reserved for a future confidence-scored detector;
+	// this codebase's detectors are currently boolean match/no-match, so
+	// the field is accepted and carried through but not yet enforced.
+	MinConfidence float64 `yaml:"min_confidence"`
+
+	// RateLimitPerSec caps detections published for this chat, in
+	// messages per second. Zero means unlimited.
+	RateLimitPerSec float64 `yaml:"rate_limit_per_sec"`
+
+	// OutputStream overrides the default detection stream for this chat.
+	// Empty means "use the global stream name".
+	OutputStream string `yaml:"output_stream"`
+}
+
+// UserFilters is an allow/deny list of Telegram user IDs. "*" matches any
+// user. Deny is checked before Allow.
+type UserFilters struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// DetectorsConfig is the global (non-chat-specific) detector selection.
+type DetectorsConfig struct {
+	Enabled []string `yaml:"enabled"`
+}
+
+// Config is the full contents of the YAML file pointed to by CONFIG_FILE.
+type Config struct {
+	MonitoredChats []ChatConfig    `yaml:"monitored_chats"`
+	UserFilters    UserFilters     `yaml:"user_filters"`
+	Detectors      DetectorsConfig `yaml:"detectors"`
+}
+
+// Load reads and parses the YAML file at path. A missing file is not an
+// error — it returns an empty Config (no monitored chats, no user filters,
+// no detectors), so a deploy that hasn't been given a CONFIG_FILE yet
+// degrades to "watching nothing" instead of failing to start.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: read %q: %w", path, err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("config: parse %q: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Chat returns the ChatConfig for chatID and whether it is both present and
+// enabled.
+func (c *Config) Chat(chatID int64) (ChatConfig, bool) {
+	for _, cc := range c.MonitoredChats {
+		if cc.ChatID == chatID {
+			return cc, cc.Enabled
+		}
+	}
+	return ChatConfig{}, false
+}
+
+// AllowsUser applies UserFilters to userID: a deny match (including "*")
+// rejects outright; otherwise an empty allow list permits everyone, and a
+// non-empty one requires a match (including "*").
+func (c *Config) AllowsUser(userID int64) bool {
+	uid := fmt.Sprintf("%d", userID)
+	for _, d := range c.UserFilters.Deny {
+		if d == "*" || d == uid {
+			return false
+		}
+	}
+	if len(c.UserFilters.Allow) == 0 {
+		return true
+	}
+	for _, a := range c.UserFilters.Allow {
+		if a == "*" || a == uid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// ReloadChannel is the Redis pub/sub channel that, when published to,
+// triggers an immediate Store reload (in addition to SIGHUP).
+const ReloadChannel = "config:reload"
+
+// Store holds the current Config behind an atomic.Pointer so readers on
+// the hot path (Config.Chat, Config.AllowsUser on the snapshot from Get)
+// never block on a reload in progress.
+type Store struct {
+	path    string
+	current atomic.Pointer[Config]
+}
+
+// NewStore loads path once and returns a Store, or an error if the initial
+// load fails.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads and re-parses the config file, atomically swapping it in
+// on success. A parse failure leaves the previous snapshot in place.
+func (s *Store) Reload() error {
+	cfg, err := Load(s.path)
+	if err != nil {
+		return err
+	}
+	s.current.Store(cfg)
+	return nil
+}
+
+// Get returns the current config snapshot. Safe to call concurrently.
+func (s *Store) Get() *Config {
+	return s.current.Load()
+}
+
+// WatchSignals reloads the store whenever the process receives SIGHUP, until
+// ctx is cancelled.
+func (s *Store) WatchSignals(ctx context.Context, logger *zap.Logger) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigCh:
+			if err := s.Reload(); err != nil {
+				logger.Error("config reload via SIGHUP failed", zap.Error(err))
+			} else {
+				logger.Info("config reloaded via SIGHUP")
+			}
+		}
+	}
+}
+
+// WatchPubSub reloads the store whenever a message arrives on ReloadChannel,
+// until ctx is cancelled.
+func (s *Store) WatchPubSub(ctx context.Context, rdb redis.UniversalClient, logger *zap.Logger) {
+	sub := rdb.Subscribe(ctx, ReloadChannel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := s.Reload(); err != nil {
+				logger.Error("config reload via pubsub failed", zap.Error(err))
+			} else {
+				logger.Info("config reloaded via pubsub", zap.String("channel", ReloadChannel))
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package detector
+
+import "regexp"
+
+var aptosPattern = regexp.MustCompile(`\b0x[0-9a-fA-F]{64}\b`)
+
+// AptosDetector finds Aptos/Sui-style account addresses: 0x followed by a
+// 32-byte (64 hex character) value. The longer hex width keeps these from
+// colliding with EVMDetector's 20-byte addresses.
+type AptosDetector struct{}
+
+// NewAptosDetector returns an AptosDetector.
+func NewAptosDetector() *AptosDetector { return &AptosDetector{} }
+
+// Name implements Detector.
+func (d *AptosDetector) Name() string { return "aptos" }
+
+// Find implements Detector.
+func (d *AptosDetector) Find(text string) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+	for _, m := range aptosPattern.FindAllString(text, -1) {
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		matches = append(matches, Match{Chain: "aptos", Format: FormatStandard, Address: m, Original: m})
+	}
+	return matches
+}
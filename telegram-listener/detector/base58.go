@@ -0,0 +1,48 @@
+package detector
+
+import "fmt"
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var base58Index [256]int8
+
+func init() {
+	for i := range base58Index {
+		base58Index[i] = -1
+	}
+	for i, c := range base58Alphabet {
+		base58Index[c] = int8(i)
+	}
+}
+
+// decodeBase58 decodes s to its raw bytes. This is used to reject strings
+// that merely use base58-alphabet characters but don't decode to a valid
+// fixed-width payload (what the old regex-only check accepted).
+func decodeBase58(s string) ([]byte, error) {
+	result := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c > 255 || base58Index[c] < 0 {
+			return nil, fmt.Errorf("detector: invalid base58 character %q", c)
+		}
+		carry := int(base58Index[c])
+		for i := 0; i < len(result); i++ {
+			carry += int(result[i]) * 58
+			result[i] = byte(carry & 0xff)
+			carry >>= 8
+		}
+		for carry > 0 {
+			result = append(result, byte(carry&0xff))
+			carry >>= 8
+		}
+	}
+	for _, c := range s {
+		if c != '1' {
+			break
+		}
+		result = append(result, 0)
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package detector
+
+import "strings"
+
+// bech32Charset is the BIP-0173 data-part alphabet.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// isValidBech32 verifies the checksum of a "bc1..." native SegWit address
+// per BIP-0173. It only covers the original bech32 constant (witness
+// version 0, P2WPKH/P2WSH); Taproot (v1+) addresses use the distinct
+// bech32m constant from BIP-0350 and are treated as no match here rather
+// than risking a false positive from the wrong checksum algorithm.
+func isValidBech32(addr string) bool {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return false
+	}
+	addr = strings.ToLower(addr)
+
+	pos := strings.LastIndex(addr, "1")
+	if pos < 1 || pos+7 > len(addr) {
+		return false
+	}
+	hrp, dataPart := addr[:pos], addr[pos+1:]
+	if hrp != "bc" {
+		return false
+	}
+
+	data := make([]int, len(dataPart))
+	for i, c := range dataPart {
+		idx := strings.IndexRune(bech32Charset, c)
+		if idx < 0 {
+			return false
+		}
+		data[i] = idx
+	}
+	return bech32Polymod(bech32HRPExpand(hrp, data)) == 1
+}
+
+// bech32HRPExpand builds the value sequence bech32Polymod checks: the HRP's
+// high bits, a zero separator, the HRP's low bits, then the data values.
+func bech32HRPExpand(hrp string, data []int) []int {
+	values := make([]int, 0, len(hrp)*2+1+len(data))
+	for _, c := range hrp {
+		values = append(values, int(c)>>5)
+	}
+	values = append(values, 0)
+	for _, c := range hrp {
+		values = append(values, int(c)&31)
+	}
+	return append(values, data...)
+}
+
+// bech32Polymod is the BIP-0173 checksum polynomial; a valid bech32 string
+// (HRP expansion + data + checksum) evaluates to 1.
+func bech32Polymod(values []int) int {
+	gen := [5]int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := 1
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ v
+		for i, g := range gen {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
@@ -0,0 +1,23 @@
+package detector
+
+import "testing"
+
+func TestIsValidBech32(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want bool
+	}{
+		{"valid checksum", "bc15feryxhrdz9m6y09mr8wrerwzgj6f8ntpe7y20", true},
+		{"corrupted last character", "bc15feryxhrdz9m6y09mr8wrerwzgj6f8ntpe7y2s", false},
+		{"wrong hrp", "tb15feryxhrdz9m6y09mr8wrerwzgj6f8ntpe7y20", false},
+		{"mixed case", "bc15FERYxhrdz9m6y09mr8wrerwzgj6f8ntpe7y20", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidBech32(tt.addr); got != tt.want {
+				t.Errorf("isValidBech32(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package detector
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"regexp"
+)
+
+var (
+	btcBech32Pattern = regexp.MustCompile(`\bbc1[ac-hj-np-z02-9]{11,71}\b`)
+	btcBase58Pattern = regexp.MustCompile(`\b[13][1-9A-HJ-NP-Za-km-z]{25,34}\b`)
+)
+
+// BitcoinDetector finds Bitcoin addresses in both the bech32 (native
+// SegWit v0, "bc1...", checksum-verified per BIP-0173) and legacy/P2SH
+// base58check ("1..."/"3...") forms.
+type BitcoinDetector struct{}
+
+// NewBitcoinDetector returns a BitcoinDetector.
+func NewBitcoinDetector() *BitcoinDetector { return &BitcoinDetector{} }
+
+// Name implements Detector.
+func (d *BitcoinDetector) Name() string { return "bitcoin" }
+
+// Find implements Detector.
+func (d *BitcoinDetector) Find(text string) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+
+	for _, m := range btcBech32Pattern.FindAllString(text, -1) {
+		if seen[m] || !isValidBech32(m) {
+			continue
+		}
+		seen[m] = true
+		matches = append(matches, Match{Chain: "bitcoin", Format: FormatStandard, Address: m, Original: m})
+	}
+
+	for _, m := range btcBase58Pattern.FindAllString(text, -1) {
+		if seen[m] || !isValidBase58Check(m) {
+			continue
+		}
+		seen[m] = true
+		matches = append(matches, Match{Chain: "bitcoin", Format: FormatStandard, Address: m, Original: m})
+	}
+
+	return matches
+}
+
+// isValidBase58Check verifies the trailing 4-byte checksum used by legacy
+// and P2SH addresses: checksum == sha256(sha256(payload))[:4].
+func isValidBase58Check(addr string) bool {
+	decoded, err := decodeBase58(addr)
+	if err != nil || len(decoded) < 5 {
+		return false
+	}
+	payload, checksum := decoded[:len(decoded)-4], decoded[len(decoded)-4:]
+	sum := doubleSHA256(payload)
+	return bytes.Equal(sum[:4], checksum)
+}
+
+func doubleSHA256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
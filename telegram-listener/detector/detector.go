@@ -0,0 +1,28 @@
+// Package detector finds chain addresses in free text. Each chain gets its
+// own Detector implementation so chain-specific heuristics (obfuscation,
+// split-line reassembly, checksum validation) don't leak into one another;
+// a Registry runs the set enabled by Config.EnabledDetectors over a message.
+package detector
+
+// Format describes how an address appeared in the source text.
+type Format string
+
+const (
+	FormatStandard   Format = "standard"
+	FormatObfuscated Format = "obfuscated"
+	FormatSplit      Format = "split"
+)
+
+// Match is a single chain address found in free text.
+type Match struct {
+	Chain    string
+	Format   Format
+	Address  string
+	Original string
+}
+
+// Detector finds addresses for one chain in free text.
+type Detector interface {
+	Name() string
+	Find(text string) []Match
+}
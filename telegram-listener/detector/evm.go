@@ -0,0 +1,75 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var evmPattern = regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`)
+
+// EVMDetector finds EVM-style addresses: 0x followed by 40 hex characters.
+// Mixed-case addresses are verified against EIP-55 so a single bit-flipped
+// hex character doesn't get treated as a valid address.
+type EVMDetector struct{}
+
+// NewEVMDetector returns an EVMDetector.
+func NewEVMDetector() *EVMDetector { return &EVMDetector{} }
+
+// Name implements Detector.
+func (d *EVMDetector) Name() string { return "evm" }
+
+// Find implements Detector.
+func (d *EVMDetector) Find(text string) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+	for _, m := range evmPattern.FindAllString(text, -1) {
+		if seen[m] || !isPlausibleEVMAddress(m) {
+			continue
+		}
+		seen[m] = true
+		matches = append(matches, Match{Chain: "evm", Format: FormatStandard, Address: m, Original: m})
+	}
+	return matches
+}
+
+// isPlausibleEVMAddress accepts addresses with no checksum casing (all
+// lower or all upper hex) as well as properly EIP-55-checksummed ones.
+func isPlausibleEVMAddress(addr string) bool {
+	hex := addr[2:]
+	if hex == strings.ToLower(hex) || hex == strings.ToUpper(hex) {
+		return true
+	}
+	return hex == toChecksumAddress(hex)
+}
+
+// toChecksumAddress implements EIP-55: uppercase hex digit i of the lower-
+// cased address whenever the i-th nibble of keccak256(lowercased address)
+// is >= 8.
+func toChecksumAddress(hexAddr string) string {
+	lower := strings.ToLower(hexAddr)
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	sum := hash.Sum(nil)
+
+	var b strings.Builder
+	for i, c := range lower {
+		if c < 'a' || c > 'f' {
+			b.WriteRune(c)
+			continue
+		}
+		var nibble byte
+		if i%2 == 0 {
+			nibble = sum[i/2] >> 4
+		} else {
+			nibble = sum[i/2] & 0x0f
+		}
+		if nibble >= 8 {
+			b.WriteRune(c - 32)
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package detector
+
+import "testing"
+
+func TestToChecksumAddress(t *testing.T) {
+	// Canonical EIP-55 test vectors (from the EIP-55 spec examples).
+	tests := []struct {
+		lower string
+		want  string
+	}{
+		{"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{"fb6916095ca1df60bb79ce92ce3ea74c37c5d359", "fB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"},
+		{"dbf03b407c01e7cd3cbea99509d93f8dddc8c6fb", "dbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB"},
+		{"d1220a0cf47c7b9be7a2e6ba89f429762e7b9adb", "D1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb"},
+	}
+	for _, tt := range tests {
+		if got := toChecksumAddress(tt.lower); got != tt.want {
+			t.Errorf("toChecksumAddress(%q) = %q, want %q", tt.lower, got, tt.want)
+		}
+	}
+}
+
+func TestIsPlausibleEVMAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want bool
+	}{
+		{"all lowercase, no checksum claimed", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"all uppercase, no checksum claimed", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", true},
+		{"valid EIP-55 checksum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", true},
+		{"mixed case with wrong checksum", "0x5aaEb6053F3E94C9b9A09f33669435E7Ef1BeAed", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPlausibleEVMAddress(tt.addr); got != tt.want {
+				t.Errorf("isPlausibleEVMAddress(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
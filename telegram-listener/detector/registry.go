@@ -0,0 +1,58 @@
+package detector
+
+import "fmt"
+
+// Registry runs a fixed set of Detectors over text and dedupes matches that
+// more than one detector agrees on.
+type Registry struct {
+	detectors []Detector
+}
+
+// NewRegistry returns a Registry that runs each of detectors, in order.
+func NewRegistry(detectors ...Detector) *Registry {
+	return &Registry{detectors: detectors}
+}
+
+// Find runs every detector in the registry and returns the combined,
+// deduped matches.
+func (r *Registry) Find(text string) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+	for _, d := range r.detectors {
+		for _, m := range d.Find(text) {
+			key := m.Chain + ":" + m.Address
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// available lists every built-in detector by the name used in
+// Config.EnabledDetectors.
+func available() map[string]Detector {
+	return map[string]Detector{
+		"solana":  NewSolanaDetector(),
+		"evm":     NewEVMDetector(),
+		"bitcoin": NewBitcoinDetector(),
+		"aptos":   NewAptosDetector(),
+	}
+}
+
+// BuildRegistry constructs a Registry from Config.EnabledDetectors, erroring
+// on any name that doesn't match a built-in detector.
+func BuildRegistry(names []string) (*Registry, error) {
+	all := available()
+	ds := make([]Detector, 0, len(names))
+	for _, name := range names {
+		d, ok := all[name]
+		if !ok {
+			return nil, fmt.Errorf("detector: unknown detector %q", name)
+		}
+		ds = append(ds, d)
+	}
+	return NewRegistry(ds...), nil
+}
@@ -0,0 +1,82 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	solStandardPattern   = regexp.MustCompile(`\b[1-9A-HJ-NP-Za-km-z]{32,44}\b`)
+	solObfuscatedPattern = regexp.MustCompile(`[1-9A-HJ-NP-Za-km-z]{8,}[-_.\s]{1,2}[1-9A-HJ-NP-Za-km-z]{8,}(?:[-_.\s]{1,2}[1-9A-HJ-NP-Za-km-z]{8,})*`)
+	solNonBase58Chars    = regexp.MustCompile(`[^1-9A-HJ-NP-Za-km-z]`)
+)
+
+// SolanaDetector finds Solana addresses: base58-encoded 32-byte values,
+// optionally written with separators between chunks or split across two
+// consecutive lines to dodge naive scanners.
+type SolanaDetector struct{}
+
+// NewSolanaDetector returns a SolanaDetector.
+func NewSolanaDetector() *SolanaDetector { return &SolanaDetector{} }
+
+// Name implements Detector.
+func (d *SolanaDetector) Name() string { return "solana" }
+
+// Find implements Detector.
+func (d *SolanaDetector) Find(text string) []Match {
+	var matches []Match
+	seen := make(map[string]bool)
+
+	for _, m := range solStandardPattern.FindAllString(text, -1) {
+		if !isValidSolanaAddress(m) || seen[m] {
+			continue
+		}
+		seen[m] = true
+		matches = append(matches, Match{Chain: "solana", Format: FormatStandard, Address: m, Original: m})
+	}
+
+	for _, m := range solObfuscatedPattern.FindAllString(text, -1) {
+		cleaned := stripSeparators(m)
+		if !isValidSolanaAddress(cleaned) || seen[cleaned] {
+			continue
+		}
+		seen[cleaned] = true
+		matches = append(matches, Match{Chain: "solana", Format: FormatObfuscated, Address: cleaned, Original: m})
+	}
+
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines)-1; i++ {
+		combined := strings.TrimSpace(lines[i]) + strings.TrimSpace(lines[i+1])
+		cleaned := solNonBase58Chars.ReplaceAllString(combined, "")
+		if !isValidSolanaAddress(cleaned) || seen[cleaned] {
+			continue
+		}
+		seen[cleaned] = true
+		matches = append(matches, Match{Chain: "solana", Format: FormatSplit, Address: cleaned, Original: combined})
+	}
+
+	return matches
+}
+
+func stripSeparators(s string) string {
+	s = strings.ReplaceAll(s, "-", "")
+	s = strings.ReplaceAll(s, "_", "")
+	s = strings.ReplaceAll(s, ".", "")
+	s = strings.ReplaceAll(s, " ", "")
+	return s
+}
+
+// isValidSolanaAddress checks the length bounds and that the string
+// actually base58-decodes to a 32-byte public key, rejecting valid-alphabet
+// strings that don't (the false positives the old length-only regex let
+// through).
+func isValidSolanaAddress(addr string) bool {
+	if len(addr) < 32 || len(addr) > 44 {
+		return false
+	}
+	decoded, err := decodeBase58(addr)
+	if err != nil {
+		return false
+	}
+	return len(decoded) == 32
+}
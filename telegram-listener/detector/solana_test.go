@@ -0,0 +1,25 @@
+package detector
+
+import "testing"
+
+func TestIsValidSolanaAddress(t *testing.T) {
+	tests := []struct {
+		name string
+		addr string
+		want bool
+	}{
+		{"wrapped SOL mint", "So11111111111111111111111111111111111111112", true},
+		{"system program", "11111111111111111111111111111111", true},
+		{"SPL Token program", "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA", true},
+		{"44-char base58 that decodes to 33 bytes", "h82pJGF9p7kpzb6eU326EFZf2cDnimbTFVeJtx1qtBmU", false},
+		{"too short", "11111111111111111111111111111", false},
+		{"contains non-base58 character", "So1111111111111111111111111111111111111110", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidSolanaAddress(tt.addr); got != tt.want {
+				t.Errorf("isValidSolanaAddress(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package floodwait wraps a Telegram RPC invoker so that a FLOOD_WAIT_x
+// error is handled transparently — sleep for the requested duration and
+// retry — instead of bubbling up to the caller as a failure.
+package floodwait
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// Metrics is the subset of instrumentation floodwait needs, kept narrow so
+// this package doesn't depend on the top-level metrics package.
+type Metrics interface {
+	ObserveFloodWait(seconds float64)
+}
+
+// Invoker wraps a tg.Invoker, sleeping and retrying on FLOOD_WAIT_x until it
+// succeeds or the wait would exceed MaxWait.
+type Invoker struct {
+	next    tg.Invoker
+	metrics Metrics
+	maxWait time.Duration
+}
+
+// Wrap returns an Invoker around next. A maxWait of zero means no bound —
+// the invoker will sleep for however long the server asks.
+func Wrap(next tg.Invoker, metrics Metrics, maxWait time.Duration) *Invoker {
+	return &Invoker{next: next, metrics: metrics, maxWait: maxWait}
+}
+
+// InvokeRaw implements tg.Invoker.
+func (i *Invoker) InvokeRaw(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+	for {
+		err := i.next.InvokeRaw(ctx, input, output)
+		wait, ok := tgerr.AsFloodWait(err)
+		if !ok {
+			return err
+		}
+		if i.maxWait > 0 && wait > i.maxWait {
+			return err
+		}
+		if i.metrics != nil {
+			i.metrics.ObserveFloodWait(wait.Seconds())
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Middleware adapts Invoker to telegram.Client's Middlewares option, so it
+// can be installed with telegram.Options{Middlewares: []telegram.Middleware{
+// floodwait.NewMiddleware(m, maxWait)}}.
+type Middleware struct {
+	metrics Metrics
+	maxWait time.Duration
+}
+
+// NewMiddleware returns a Middleware that reports waits to metrics (which
+// may be nil) and bounds a single wait to maxWait (zero for unbounded).
+func NewMiddleware(metrics Metrics, maxWait time.Duration) Middleware {
+	return Middleware{metrics: metrics, maxWait: maxWait}
+}
+
+// Handle implements telegram.Middleware.
+func (m Middleware) Handle(next tg.Invoker) tg.Invoker {
+	return Wrap(next, m.metrics, m.maxWait)
+}
@@ -4,12 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
-	"regexp"
 	"strconv"
 	"strings"
-	"sync/atomic"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -19,47 +18,42 @@ import (
 	"github.com/gotd/td/telegram/updates"
 	"github.com/gotd/td/tg"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/config"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/detector"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/floodwait"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/metrics"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/pool"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/redisconn"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/session"
+	"github.com/DegenPotato/cex-dev-monitor/telegram-listener/streampub"
 )
 
 const (
-	// Schema version for message format
-	SCHEMA_VERSION = "1.0.0"
-	
-	// Metrics tracking intervals
-	METRICS_LOG_INTERVAL = 30 * time.Second
-	REDIS_PING_INTERVAL  = 5 * time.Second
-)
+	// Schema version for message format. Bumped to 2.0.0 when the
+	// single-chain "type" field was replaced by "chain"+"format" — a
+	// breaking rename downstream consumers need to detect.
+	SCHEMA_VERSION = "2.0.0"
 
-// Contract detection patterns
-var (
-	solPattern            = regexp.MustCompile(`\b[1-9A-HJ-NP-Za-km-z]{32,44}\b`)
-	solPatternWithSpecial = regexp.MustCompile(`[1-9A-HJ-NP-Za-km-z]{8,}[-_.\s]{1,2}[1-9A-HJ-NP-Za-km-z]{8,}(?:[-_.\s]{1,2}[1-9A-HJ-NP-Za-km-z]{8,})*`)
+	// Redis health check interval
+	REDIS_PING_INTERVAL = 5 * time.Second
 )
 
 // Detection represents a contract detection with schema versioning
 type Detection struct {
-	SchemaVersion string `json:"schema_version"`
-	UserID        int    `json:"user_id"`
-	ChatID        int64  `json:"chat_id"`
-	MessageID     int    `json:"message_id"`
-	Contract      string `json:"contract"`
-	Type          string `json:"type"`
-	Sender        int64  `json:"sender_id"`
-	Username      string `json:"username"`
-	Message       string `json:"message"`
-	DetectedAt    int64  `json:"detected_at"`
-	ProcessedAt   int64  `json:"processed_at"` // Track Go processing time
-}
-
-// Metrics tracks performance statistics
-type Metrics struct {
-	MessagesProcessed  uint64
-	ContractsDetected  uint64
-	RedisWrites        uint64
-	RedisErrors        uint64
-	AvgProcessingTime  int64 // microseconds
-	LastError          string
-	StartTime          time.Time
+	SchemaVersion string          `json:"schema_version"`
+	UserID        int             `json:"user_id"`
+	ChatID        int64           `json:"chat_id"`
+	MessageID     int             `json:"message_id"`
+	Contract      string          `json:"contract"`
+	Chain         string          `json:"chain"`
+	Format        detector.Format `json:"format"`
+	Sender        int64           `json:"sender_id"`
+	Username      string          `json:"username"`
+	Message       string          `json:"message"`
+	DetectedAt    int64           `json:"detected_at"`
+	ProcessedAt   int64           `json:"processed_at"` // Track Go processing time
 }
 
 // Config holds the app configuration
@@ -68,259 +62,456 @@ type Config struct {
 	APIHash     string
 	Phone       string
 	SessionFile string
-	RedisAddr   string
-	MonitoredChats []int64
-	UserFilters    []int64
+
+	// Phones is one or more accounts to run as a sharded pool, each
+	// monitoring the subset of chats where ChatID % len(Phones) equals its
+	// index. Defaults to []string{Phone} — a pool of one.
+	Phones []string
+
+	// SessionBackend is "file" (one FileSessionStorage per phone, suffixed
+	// by shard index) or "redis" (RedisSessionStorage, for stateless
+	// containers).
+	SessionBackend string
+
+	// FloodWaitMaxWait bounds how long a single FLOOD_WAIT_x sleep is
+	// allowed to run before the error is returned to the caller instead.
+	// Zero means unbounded.
+	FloodWaitMaxWait time.Duration
+
+	// AuthCodeTimeout bounds how long the login flow waits on
+	// "auth:code:<phone>" before falling back to a stdin prompt.
+	AuthCodeTimeout time.Duration
+
+	RedisURL             string
+	RedisDB              int
+	RedisPassword        string
+	RedisTLS             bool
+	SentinelMasterName   string
+	ClusterRouteRandomly bool
+
+	// ConfigFile is the YAML file describing monitored chats, user filters,
+	// and detector selection. It is hot-reloadable via SIGHUP or a
+	// "config:reload" Redis pub/sub message.
+	ConfigFile string
+
+	// MetricsAddr serves /metrics, /healthz, and /readyz.
+	MetricsAddr string
+
+	// Stream publishing: capping, trimming, and dedupe.
+	StreamName    string
+	MaxLen        int64
+	ApproxTrim    bool
+	DedupeTTL     time.Duration
+	ConsumerGroup string
+	MaxDeliveries int64
+	IdleReclaim   time.Duration
 }
 
 func main() {
+	redisURL := getEnv("REDIS_URL", "redis://"+getEnv("REDIS_ADDR", "localhost:6379"))
+	phone := getEnv("PHONE", "+66642397038")
 	cfg := &Config{
 		APIID:       getEnvInt("API_ID", 26373394),
 		APIHash:     getEnv("API_HASH", "45c5edf0039ffdd8efe7965189b42141"),
-		Phone:       getEnv("PHONE", "+66642397038"),
+		Phone:       phone,
 		SessionFile: getEnv("SESSION_FILE", "telegram.session"),
-		RedisAddr:   getEnv("REDIS_ADDR", "localhost:6379"),
-		MonitoredChats: []int64{-4945112939}, // Your GROUP_TARGETS
-		UserFilters:    []int64{448480473},   // Your USER_FILTER
-	}
 
-	// Initialize metrics
-	metrics := &Metrics{
-		StartTime: time.Now(),
+		Phones:           splitAndTrim(getEnv("PHONES", phone)),
+		SessionBackend:   getEnv("SESSION_BACKEND", "file"),
+		FloodWaitMaxWait: time.Duration(getEnvInt("FLOOD_WAIT_MAX_SECONDS", 300)) * time.Second,
+		AuthCodeTimeout:  time.Duration(getEnvInt("AUTH_CODE_REDIS_TIMEOUT_SECONDS", 60)) * time.Second,
+
+		RedisURL:             redisURL,
+		RedisDB:              getEnvInt("REDIS_DB", 0),
+		RedisPassword:        getEnv("REDIS_PASSWORD", redisconn.ParsePassword(redisURL)),
+		RedisTLS:             getEnv("REDIS_TLS", "") == "true",
+		SentinelMasterName:   getEnv("REDIS_SENTINEL_MASTER", ""),
+		ClusterRouteRandomly: getEnv("REDIS_CLUSTER_ROUTE_RANDOMLY", "") == "true",
+
+		ConfigFile: getEnv("CONFIG_FILE", "config.yaml"),
+
+		MetricsAddr: getEnv("METRICS_ADDR", ":9090"),
+
+		StreamName:    getEnv("STREAM_NAME", "telegram:detections"),
+		MaxLen:        int64(getEnvInt("STREAM_MAXLEN", 100000)),
+		ApproxTrim:    getEnv("STREAM_APPROX_TRIM", "true") != "false",
+		DedupeTTL:     time.Duration(getEnvInt("DEDUPE_TTL_SECONDS", 300)) * time.Second,
+		ConsumerGroup: getEnv("STREAM_CONSUMER_GROUP", "traders"),
+		MaxDeliveries: int64(getEnvInt("STREAM_MAX_DELIVERIES", 5)),
+		IdleReclaim:   time.Duration(getEnvInt("STREAM_IDLE_RECLAIM_SECONDS", 60)) * time.Second,
 	}
 
+	// Setup logger
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	// Prometheus instruments and /metrics, /healthz, /readyz
+	m := metrics.New()
+	health := metrics.NewHealthServer()
+	go func() {
+		logger.Info("metrics server listening", zap.String("addr", cfg.MetricsAddr))
+		if err := http.ListenAndServe(cfg.MetricsAddr, health.Handler()); err != nil {
+			logger.Error("metrics server stopped", zap.Error(err))
+		}
+	}()
+
 	// Setup Redis with reconnection
-	rdb := setupRedisWithReconnect(cfg.RedisAddr, metrics)
+	rdb, err := setupRedisWithReconnect(cfg, logger, m)
+	if err != nil {
+		logger.Fatal("redis setup failed", zap.Error(err))
+	}
 	defer rdb.Close()
 
-	// Start metrics logger
-	go logMetrics(metrics)
+	// Publisher: at-least-once XADD with MAXLEN trim and SET NX EX dedupe
+	publisher := streampub.New(rdb, streampub.Config{
+		Stream:     cfg.StreamName,
+		MaxLen:     cfg.MaxLen,
+		ApproxTrim: cfg.ApproxTrim,
+		DedupeTTL:  cfg.DedupeTTL,
+	})
+
+	// Runtime-reloadable monitored chats / user filters / detector
+	// selection, hot-reloaded on SIGHUP or a "config:reload" pub/sub message
+	configStore, err := config.NewStore(cfg.ConfigFile)
+	if err != nil {
+		logger.Fatal("config load failed", zap.Error(err))
+	}
+	watchCtx, cancelWatch := context.WithCancel(context.Background())
+	defer cancelWatch()
+	go configStore.WatchSignals(watchCtx, logger)
+	go configStore.WatchPubSub(watchCtx, rdb, logger)
+
+	// Chain address detector registries, cached by detector-name set since
+	// a chat's EnabledDetectors override would otherwise rebuild one per message
+	detectors := newDetectorCache()
+
+	// Per-chat rate limiters, built lazily from each chat's RateLimitPerSec
+	limiters := newRateLimiters()
 
 	// Start Redis health checker
-	go redisHealthCheck(rdb, metrics)
+	go redisHealthCheck(rdb, logger, m, health)
+
+	// Build one shard per configured phone. Each shard owns its own client,
+	// session storage, and update gap state; the pool splits monitored
+	// chats across shards by ChatID % len(shards), so a handful of accounts
+	// can cover far more chats than one account's rate limits would allow.
+	shards := make([]*pool.Shard, 0, len(cfg.Phones))
+	for i, phone := range cfg.Phones {
+		shard := newShard(i, phone, cfg, rdb, m, logger)
+		registerHandler(shard, len(cfg.Phones), cfg, configStore, detectors, limiters, publisher, m, logger)
+		shards = append(shards, shard)
+	}
+	shardPool := pool.New(shards)
 
-	// Setup logger
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
+	if err := shardPool.Run(context.Background(), logger, func(ctx context.Context, shard *pool.Shard) error {
+		return runShard(ctx, cfg, rdb, shard, logger)
+	}); err != nil {
+		logger.Fatal("shard pool stopped", zap.Error(err))
+	}
+}
+
+// newShard builds the index-th shard for phone: a telegram.Client (with
+// FLOOD_WAIT middleware and the configured session backend) plus its own
+// dispatcher and gap-tracking updates.Manager.
+func newShard(index int, phone string, cfg *Config, rdb redis.UniversalClient, m *metrics.Metrics, logger *zap.Logger) *pool.Shard {
+	var sessionStorage telegram.SessionStorage
+	if cfg.SessionBackend == "redis" {
+		sessionStorage = session.NewRedisSessionStorage(rdb, phone)
+	} else {
+		sessionStorage = &FileSessionStorage{Path: fmt.Sprintf("%s.%d", cfg.SessionFile, index)}
+	}
 
-	// Create Telegram client
 	client := telegram.NewClient(cfg.APIID, cfg.APIHash, telegram.Options{
-		Logger:        logger,
-		SessionStorage: &FileSessionStorage{Path: cfg.SessionFile},
+		Logger:         logger,
+		SessionStorage: sessionStorage,
+		Middlewares:    []telegram.Middleware{floodwait.NewMiddleware(m, cfg.FloodWaitMaxWait)},
 	})
 
-	// Setup message handler
 	dispatcher := updates.NewDispatcher()
 	gaps := updates.New(updates.Config{
 		Handler: dispatcher,
+		Storage: pool.NewRedisStateStorage(rdb, phone),
 	})
 
-	// Message handler with metrics
-	dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
+	return &pool.Shard{
+		Index:      index,
+		Phone:      phone,
+		Client:     client,
+		Dispatcher: dispatcher,
+		Gaps:       gaps,
+	}
+}
+
+// registerHandler wires shard's dispatcher to the same detection pipeline
+// every shard shares, skipping any chat not owned by this shard under
+// ChatID % shardCount sharding.
+func registerHandler(shard *pool.Shard, shardCount int, cfg *Config, configStore *config.Store, detectors *detectorCache, limiters *rateLimiters, publisher *streampub.Publisher, m *metrics.Metrics, logger *zap.Logger) {
+	shard.Dispatcher.OnNewMessage(func(ctx context.Context, e tg.Entities, update *tg.UpdateNewMessage) error {
 		startTime := time.Now()
-		atomic.AddUint64(&metrics.MessagesProcessed, 1)
-		
+		m.MessagesProcessed.Inc()
+
 		msg, ok := update.Message.(*tg.Message)
 		if !ok || msg.Message == "" {
 			return nil
 		}
 
-		// Check if from monitored chat
-		chatID := msg.PeerID.(*tg.PeerChat).ChatID
-		if !isMonitoredChat(chatID, cfg.MonitoredChats) {
+		// Check if from a monitored, enabled chat
+		chatID, ok := peerChatID(msg.PeerID)
+		if !ok {
+			return nil
+		}
+		if !ownsChat(shard.Index, shardCount, chatID) {
+			return nil
+		}
+		cfgSnapshot := configStore.Get()
+		chatCfg, monitored := cfgSnapshot.Chat(chatID)
+		if !monitored {
 			return nil
 		}
 
 		// Check user filter
-		if len(cfg.UserFilters) > 0 && !isFilteredUser(msg.FromID, cfg.UserFilters) {
+		if !senderAllowed(msg.FromID, cfgSnapshot) {
 			return nil
 		}
 
-		// Extract contracts
-		contracts := extractContracts(msg.Message)
-		if len(contracts) == 0 {
+		// senderAllowed deliberately lets a nil FromID (anonymous admin
+		// post) or a *tg.PeerChannel (channel-signed post) through
+		// unfiltered, so this can't assume *tg.PeerUser: fall back to
+		// senderID 0 (and skip the username lookup below) rather than
+		// dropping a detection from an otherwise-monitored chat.
+		var senderID int64
+		if userPeer, ok := msg.FromID.(*tg.PeerUser); ok {
+			senderID = userPeer.UserID
+		}
+
+		// Extract contracts, using this chat's detector override if it has one
+		enabledDetectors := cfgSnapshot.Detectors.Enabled
+		if len(chatCfg.EnabledDetectors) > 0 {
+			enabledDetectors = chatCfg.EnabledDetectors
+		}
+		registry, err := detectors.get(enabledDetectors)
+		if err != nil {
+			logger.Error("detector registry build failed", zap.Int64("chat_id", chatID), zap.Error(err))
 			return nil
 		}
 
+		extractStart := time.Now()
+		matches := registry.Find(msg.Message)
+		m.ExtractDuration.Observe(time.Since(extractStart).Seconds())
+		if len(matches) == 0 {
+			return nil
+		}
+
+		if !limiters.allow(chatID, chatCfg.RateLimitPerSec) {
+			return nil
+		}
+
+		stream := cfg.StreamName
+		if chatCfg.OutputStream != "" {
+			stream = chatCfg.OutputStream
+		}
+
 		// Get sender info
 		var username string
-		if user, err := e.Users[msg.FromID.(*tg.PeerUser).UserID]; err == nil {
+		if user, ok := e.Users[senderID]; ok {
 			username = user.Username
 		}
 
 		// Push each detection to Redis with metrics
-		for _, contract := range contracts {
+		for _, match := range matches {
 			detection := Detection{
 				SchemaVersion: SCHEMA_VERSION,
 				UserID:        1, // Your user ID from auth
 				ChatID:        chatID,
 				MessageID:     msg.ID,
-				Contract:      contract.Address,
-				Type:          contract.Type,
-				Sender:        msg.FromID.(*tg.PeerUser).UserID,
+				Contract:      match.Address,
+				Chain:         match.Chain,
+				Format:        match.Format,
+				Sender:        senderID,
 				Username:      username,
 				Message:       msg.Message,
 				DetectedAt:    time.Now().Unix(),
 				ProcessedAt:   time.Now().UnixMicro(),
 			}
 
-			// Push to Redis stream for real-time processing
+			// Push to Redis stream for real-time processing, deduped so a
+			// retried/duplicated update never publishes the same detection twice
 			data, _ := json.Marshal(detection)
-			err := rdb.XAdd(ctx, &redis.XAddArgs{
-				Stream: "telegram:detections",
-				Values: map[string]interface{}{
-					"data": string(data),
-				},
-			}).Err()
+			dedupeKey := streampub.DedupeKey(chatID, msg.ID, match.Address)
+			xaddStart := time.Now()
+			published, err := publisher.PublishTo(ctx, stream, dedupeKey, map[string]interface{}{
+				"data": string(data),
+			})
+			m.RedisXAddDuration.Observe(time.Since(xaddStart).Seconds())
 
+			logFields := []zap.Field{
+				zap.Int64("chat_id", chatID),
+				zap.Int64("sender_id", senderID),
+				zap.String("contract", match.Address),
+				zap.Int64("latency_us", time.Since(startTime).Microseconds()),
+			}
 			if err != nil {
-				atomic.AddUint64(&metrics.RedisErrors, 1)
-				metrics.LastError = err.Error()
-				log.Printf("❌ Redis error: %v", err)
-			} else {
-				atomic.AddUint64(&metrics.RedisWrites, 1)
-				atomic.AddUint64(&metrics.ContractsDetected, 1)
-				log.Printf("✅ [%s] %s... | Latency: %dµs", 
-					contract.Type, 
-					contract.Address[:8],
-					time.Since(startTime).Microseconds())
+				m.RedisErrors.WithLabelValues("xadd").Inc()
+				logger.Error("redis publish failed", append(logFields, zap.Error(err))...)
+			} else if published {
+				m.ContractsDetected.WithLabelValues(match.Chain, string(match.Format)).Inc()
+				logger.Info("detection published", append(logFields,
+					zap.String("chain", match.Chain),
+					zap.String("format", string(match.Format)),
+				)...)
 			}
 		}
-		
-		// Update average processing time
-		processingTime := time.Since(startTime).Microseconds()
-		atomic.StoreInt64(&metrics.AvgProcessingTime, processingTime)
 
 		return nil
 	})
+}
+
+// peerChatID extracts the monitor-config ChatID from a group or supergroup
+// update. Supergroups/channels (*tg.PeerChannel) are normalized to the
+// "-100<channel_id>" form Telegram clients and the Bot API use, so the same
+// IDs operators already have from other tooling work unchanged here.
+// Private messages (*tg.PeerUser) aren't chats to monitor and return false.
+func peerChatID(peer tg.PeerClass) (int64, bool) {
+	switch p := peer.(type) {
+	case *tg.PeerChat:
+		return p.ChatID, true
+	case *tg.PeerChannel:
+		return -(1000000000000 + p.ChannelID), true
+	default:
+		return 0, false
+	}
+}
+
+// ownsChat reports whether the shard at index owns chatID, sharded by
+// chatID % shardCount. Supergroup/channel IDs are negative, so the result is
+// normalized back into [0, shardCount).
+func ownsChat(index, shardCount int, chatID int64) bool {
+	n := int64(shardCount)
+	idx := chatID % n
+	if idx < 0 {
+		idx += n
+	}
+	return int(idx) == index
+}
 
-	// Run client
-	if err := client.Run(context.Background(), func(ctx context.Context) error {
-		// Authentication flow
+// runShard authenticates shard.Client and then runs the update-gap loop
+// until ctx is cancelled or it errors.
+func runShard(ctx context.Context, cfg *Config, rdb redis.UniversalClient, shard *pool.Shard, logger *zap.Logger) error {
+	return shard.Client.Run(ctx, func(ctx context.Context) error {
+		// Authentication flow. The code is first tried from
+		// "auth:code:<phone>" via BLPOP, so a headless deploy can push it in
+		// from whatever out-of-band channel delivers the SMS/app code; if
+		// nothing arrives within cfg.AuthCodeTimeout it falls back to a
+		// stdin prompt for interactive logins.
 		flow := auth.NewFlow(
-			auth.Constant(cfg.Phone,
+			auth.Constant(shard.Phone,
 				auth.CodeAuthenticatorFunc(func(ctx context.Context, sentCode *tg.AuthSentCode) (string, error) {
-					// In production, get code from Redis or HTTP endpoint
-					fmt.Print("Enter code: ")
-					var code string
-					fmt.Scanln(&code)
-					return code, nil
+					code, err := session.FetchAuthCode(ctx, rdb, shard.Phone, cfg.AuthCodeTimeout)
+					if err != nil {
+						logger.Warn("auth code redis fetch failed, falling back to stdin", zap.String("phone", shard.Phone), zap.Error(err))
+					} else if code != "" {
+						return code, nil
+					}
+					fmt.Printf("Enter code for %s: ", shard.Phone)
+					var typed string
+					fmt.Scanln(&typed)
+					return typed, nil
 				}),
 			),
 			auth.SendCodeOptions{},
 		)
 
-		if err := client.Auth().IfNecessary(ctx, flow); err != nil {
+		if err := shard.Client.Auth().IfNecessary(ctx, flow); err != nil {
+			return err
+		}
+
+		logger.Info("authenticated to Telegram", zap.String("phone", shard.Phone))
+
+		self, err := shard.Client.Self(ctx)
+		if err != nil {
 			return err
 		}
 
-		log.Println("✅ Authenticated to Telegram")
-		
 		// Start receiving updates
-		return gaps.Run(ctx, client.API(), e.Self.ID, updates.AuthOptions{
+		return shard.Gaps.Run(ctx, shard.Client.API(), self.ID, updates.AuthOptions{
 			OnStart: func(ctx context.Context) {
-				log.Println("🚀 Telegram listener started")
+				logger.Info("Telegram listener started", zap.Int("shard", shard.Index), zap.String("phone", shard.Phone))
 			},
 		})
-	}); err != nil {
-		log.Fatal(err)
+	})
+}
+
+// senderAllowed applies cfg's user filters to a message's FromID, allowing
+// messages with no sender or a non-user sender through unfiltered.
+func senderAllowed(fromID tg.PeerClass, cfg *config.Config) bool {
+	if fromID == nil {
+		return true
+	}
+	userPeer, ok := fromID.(*tg.PeerUser)
+	if !ok {
+		return true
 	}
+	return cfg.AllowsUser(userPeer.UserID)
 }
 
-// Contract represents a detected contract
-type Contract struct {
-	Address  string
-	Type     string
-	Original string
+// detectorCache builds detector.Registry values on demand and caches them
+// by their detector-name list, in the order given, so a per-chat
+// EnabledDetectors override doesn't rebuild a registry on every message.
+type detectorCache struct {
+	mu    sync.Mutex
+	byKey map[string]*detector.Registry
 }
 
-// extractContracts finds all contract addresses in text
-func extractContracts(text string) []Contract {
-	var contracts []Contract
-	seen := make(map[string]bool)
-
-	// Standard format
-	for _, match := range solPattern.FindAllString(text, -1) {
-		if isValidSolanaAddress(match) && !seen[match] {
-			contracts = append(contracts, Contract{
-				Address: match,
-				Type:    "standard",
-				Original: match,
-			})
-			seen[match] = true
-		}
-	}
+func newDetectorCache() *detectorCache {
+	return &detectorCache{byKey: make(map[string]*detector.Registry)}
+}
 
-	// Obfuscated format
-	for _, match := range solPatternWithSpecial.FindAllString(text, -1) {
-		cleaned := strings.ReplaceAll(match, "-", "")
-		cleaned = strings.ReplaceAll(cleaned, "_", "")
-		cleaned = strings.ReplaceAll(cleaned, ".", "")
-		cleaned = strings.ReplaceAll(cleaned, " ", "")
-		
-		if isValidSolanaAddress(cleaned) && !seen[cleaned] {
-			contracts = append(contracts, Contract{
-				Address:  cleaned,
-				Type:     "obfuscated",
-				Original: match,
-			})
-			seen[cleaned] = true
-		}
-	}
+func (c *detectorCache) get(names []string) (*detector.Registry, error) {
+	key := strings.Join(names, ",")
 
-	// Split format (check consecutive lines)
-	lines := strings.Split(text, "\n")
-	for i := 0; i < len(lines)-1; i++ {
-		combined := strings.TrimSpace(lines[i]) + strings.TrimSpace(lines[i+1])
-		cleaned := regexp.MustCompile(`[^1-9A-HJ-NP-Za-km-z]`).ReplaceAllString(combined, "")
-		
-		if isValidSolanaAddress(cleaned) && !seen[cleaned] {
-			contracts = append(contracts, Contract{
-				Address:  cleaned,
-				Type:     "split",
-				Original: combined,
-			})
-			seen[cleaned] = true
-		}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r, ok := c.byKey[key]; ok {
+		return r, nil
 	}
-
-	return contracts
+	r, err := detector.BuildRegistry(names)
+	if err != nil {
+		return nil, err
+	}
+	c.byKey[key] = r
+	return r, nil
 }
 
-// isValidSolanaAddress checks if string is valid Solana address
-func isValidSolanaAddress(addr string) bool {
-	if len(addr) < 32 || len(addr) > 44 {
-		return false
-	}
-	return solPattern.MatchString(addr)
+// rateLimiters tracks a token-bucket limiter per chat, created lazily from
+// that chat's configured rate.
+type rateLimiters struct {
+	mu     sync.Mutex
+	byChat map[int64]*rate.Limiter
 }
 
-// Helper functions
-func isMonitoredChat(chatID int64, monitored []int64) bool {
-	for _, id := range monitored {
-		if id == chatID {
-			return true
-		}
-	}
-	return false
+func newRateLimiters() *rateLimiters {
+	return &rateLimiters{byChat: make(map[int64]*rate.Limiter)}
 }
 
-func isFilteredUser(fromID tg.PeerClass, filters []int64) bool {
-	if fromID == nil {
-		return true // Allow if no sender
-	}
-	
-	userPeer, ok := fromID.(*tg.PeerUser)
-	if !ok {
+// allow reports whether a detection for chatID may proceed. perSec <= 0
+// means unlimited. A changed rate (after a config reload) is applied to the
+// chat's existing limiter.
+func (r *rateLimiters) allow(chatID int64, perSec float64) bool {
+	if perSec <= 0 {
 		return true
 	}
-	
-	for _, id := range filters {
-		if id == userPeer.UserID {
-			return true
-		}
+
+	r.mu.Lock()
+	lim, ok := r.byChat[chatID]
+	if !ok {
+		lim = rate.NewLimiter(rate.Limit(perSec), 1)
+		r.byChat[chatID] = lim
+	} else if lim.Limit() != rate.Limit(perSec) {
+		lim.SetLimit(rate.Limit(perSec))
 	}
-	return false
+	r.mu.Unlock()
+
+	return lim.Allow()
 }
 
 // FileSessionStorage implements session storage
@@ -340,85 +531,70 @@ func (f *FileSessionStorage) StoreSession(ctx context.Context, data []byte) erro
 	return os.WriteFile(f.Path, data, 0600)
 }
 
-// setupRedisWithReconnect creates Redis client with automatic reconnection
-func setupRedisWithReconnect(addr string, metrics *Metrics) *redis.Client {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:         addr,
-		MaxRetries:   10,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolSize:     10,
-		MinIdleConns: 5,
-		OnConnect: func(ctx context.Context, cn *redis.Conn) error {
-			log.Println("✅ Redis connected")
-			return nil
-		},
+// setupRedisWithReconnect builds a redis.UniversalClient (single node,
+// Sentinel, or cluster, depending on cfg.RedisURL) with automatic
+// reconnection.
+func setupRedisWithReconnect(cfg *Config, logger *zap.Logger, m *metrics.Metrics) (redis.UniversalClient, error) {
+	rdb, err := redisconn.New(redisconn.Config{
+		URL:                  cfg.RedisURL,
+		DB:                   cfg.RedisDB,
+		Password:             cfg.RedisPassword,
+		TLS:                  cfg.RedisTLS,
+		SentinelMasterName:   cfg.SentinelMasterName,
+		ClusterRouteRandomly: cfg.ClusterRouteRandomly,
+		MaxRetries:           10,
+		DialTimeout:          5 * time.Second,
+		ReadTimeout:          3 * time.Second,
+		WriteTimeout:         3 * time.Second,
+		PoolSize:             10,
+		MinIdleConns:         5,
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Initial connection test
 	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		log.Printf("⚠️ Initial Redis connection failed: %v (will retry)", err)
-		atomic.AddUint64(&metrics.RedisErrors, 1)
+		logger.Warn("initial Redis connection failed, will retry", zap.Error(err))
+		m.RedisErrors.WithLabelValues("ping").Inc()
+	} else {
+		logger.Info("Redis connected")
 	}
 
-	return rdb
+	return rdb, nil
 }
 
-// redisHealthCheck monitors Redis connection health
-func redisHealthCheck(rdb *redis.Client, metrics *Metrics) {
+// redisHealthCheck periodically pings Redis, feeding both the Prometheus
+// error counter and the /readyz readiness state.
+func redisHealthCheck(rdb redis.UniversalClient, logger *zap.Logger, m *metrics.Metrics, health *metrics.HealthServer) {
 	ticker := time.NewTicker(REDIS_PING_INTERVAL)
 	defer ticker.Stop()
 
 	for range ticker.C {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		if err := rdb.Ping(ctx).Err(); err != nil {
-			log.Printf("❌ Redis health check failed: %v", err)
-			atomic.AddUint64(&metrics.RedisErrors, 1)
-			metrics.LastError = fmt.Sprintf("Redis ping failed: %v", err)
-		}
+		err := rdb.Ping(ctx).Err()
 		cancel()
+
+		health.SetRedisHealthy(err == nil)
+		if err != nil {
+			m.RedisErrors.WithLabelValues("ping").Inc()
+			logger.Error("Redis health check failed", zap.Error(err))
+		}
 	}
 }
 
-// logMetrics periodically logs performance metrics
-func logMetrics(metrics *Metrics) {
-	ticker := time.NewTicker(METRICS_LOG_INTERVAL)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		uptime := time.Since(metrics.StartTime)
-		messagesProcessed := atomic.LoadUint64(&metrics.MessagesProcessed)
-		contractsDetected := atomic.LoadUint64(&metrics.ContractsDetected)
-		redisWrites := atomic.LoadUint64(&metrics.RedisWrites)
-		redisErrors := atomic.LoadUint64(&metrics.RedisErrors)
-		avgProcessingTime := atomic.LoadInt64(&metrics.AvgProcessingTime)
-
-		log.Printf(`
-📊 Telegram Listener Metrics:
-├─ Uptime: %v
-├─ Messages Processed: %d
-├─ Contracts Detected: %d
-├─ Redis Writes: %d
-├─ Redis Errors: %d
-├─ Avg Processing: %dµs
-├─ Throughput: %.2f msg/sec
-└─ Success Rate: %.2f%%`,
-			uptime,
-			messagesProcessed,
-			contractsDetected,
-			redisWrites,
-			redisErrors,
-			avgProcessingTime,
-			float64(messagesProcessed)/uptime.Seconds(),
-			(float64(redisWrites)/float64(redisWrites+redisErrors))*100,
-		)
-
-		if metrics.LastError != "" {
-			log.Printf("⚠️ Last Error: %s", metrics.LastError)
+// splitAndTrim splits raw on commas, trims whitespace from each part, and
+// drops empty ones, for comma-separated env vars like PHONES.
+func splitAndTrim(raw string) []string {
+	var out []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			out = append(out, part)
 		}
 	}
+	return out
 }
 
 // getEnv gets environment variable with fallback
@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// HealthServer serves /metrics, /healthz, and /readyz. It starts not ready
+// until SetRedisHealthy(true) is called for the first time, and flips back
+// to not-ready whenever the Redis health check goes red.
+type HealthServer struct {
+	redisHealthy atomic.Bool
+}
+
+// NewHealthServer returns a HealthServer.
+func NewHealthServer() *HealthServer {
+	return &HealthServer{}
+}
+
+// SetRedisHealthy updates the readiness state consulted by /readyz.
+func (h *HealthServer) SetRedisHealthy(healthy bool) {
+	h.redisHealthy.Store(healthy)
+}
+
+// Handler returns the mux serving /metrics, /healthz, and /readyz.
+func (h *HealthServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeStatus(w, http.StatusOK, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !h.redisHealthy.Load() {
+			writeStatus(w, http.StatusServiceUnavailable, "redis unhealthy")
+			return
+		}
+		writeStatus(w, http.StatusOK, "ready")
+	})
+	return mux
+}
+
+func writeStatus(w http.ResponseWriter, code int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
@@ -0,0 +1,57 @@
+// Package metrics defines the Prometheus instruments the listener reports,
+// along with an HTTP handler serving /metrics, /healthz, and /readyz.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics holds the listener's Prometheus instruments.
+type Metrics struct {
+	MessagesProcessed prometheus.Counter
+	ContractsDetected *prometheus.CounterVec
+	RedisXAddDuration prometheus.Histogram
+	RedisErrors       *prometheus.CounterVec
+	ExtractDuration   prometheus.Histogram
+	FloodWaitSeconds  prometheus.Histogram
+}
+
+// New registers and returns the listener's Prometheus instruments.
+func New() *Metrics {
+	return &Metrics{
+		MessagesProcessed: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "telegram_messages_processed_total",
+			Help: "Total number of Telegram messages processed.",
+		}),
+		ContractsDetected: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "telegram_contracts_detected_total",
+			Help: "Total number of chain addresses detected, by chain and format.",
+		}, []string{"chain", "format"}),
+		RedisXAddDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "redis_xadd_duration_seconds",
+			Help:    "Latency of publishing a detection to Redis.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		RedisErrors: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "redis_errors_total",
+			Help: "Total number of Redis errors, by operation.",
+		}, []string{"op"}),
+		ExtractDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "extract_duration_seconds",
+			Help:    "Latency of detector extraction over a single message.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		FloodWaitSeconds: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "telegram_flood_wait_seconds",
+			Help:    "Seconds spent sleeping on Telegram FLOOD_WAIT_x responses.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600},
+		}),
+	}
+}
+
+// ObserveFloodWait records a FLOOD_WAIT_x sleep, implementing
+// floodwait.Metrics.
+func (m *Metrics) ObserveFloodWait(seconds float64) {
+	m.FloodWaitSeconds.Observe(seconds)
+}
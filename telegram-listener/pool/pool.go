@@ -0,0 +1,78 @@
+// Package pool runs several Telegram accounts (shards) in a single process,
+// so a set of high-volume chats can be split across accounts instead of
+// hitting one account's per-API rate limits.
+package pool
+
+import (
+	"context"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/updates"
+	"go.uber.org/zap"
+)
+
+// Shard is one account's client, dispatcher, and gap-tracking state.
+type Shard struct {
+	Index      int
+	Phone      string
+	Client     *telegram.Client
+	Dispatcher *updates.Dispatcher
+	Gaps       *updates.Manager
+}
+
+// Pool holds every configured shard and decides which one owns a given chat.
+type Pool struct {
+	shards []*Shard
+}
+
+// New returns a Pool over shards. Shard.Index must equal its position in
+// shards, since OwnsChat relies on it for chatID sharding.
+func New(shards []*Shard) *Pool {
+	return &Pool{shards: shards}
+}
+
+// Shards returns every shard in the pool.
+func (p *Pool) Shards() []*Shard {
+	return p.shards
+}
+
+// Len returns the number of shards in the pool.
+func (p *Pool) Len() int {
+	return len(p.shards)
+}
+
+// OwnsChat reports whether shard is the one responsible for chatID, sharded
+// by chatID % len(shards). Supergroup/channel IDs are negative, so the
+// result is normalized back into [0, len(shards)).
+func (p *Pool) OwnsChat(shard *Shard, chatID int64) bool {
+	n := int64(len(p.shards))
+	idx := chatID % n
+	if idx < 0 {
+		idx += n
+	}
+	return int(idx) == shard.Index
+}
+
+// Run starts runShard concurrently for every shard and blocks until the
+// first one returns, cancelling the rest. A single account dying (auth
+// revoked, FLOOD_WAIT past the bound, etc.) takes down the process so it can
+// be rescheduled and re-authenticated rather than silently monitoring a
+// partial chat set.
+func (p *Pool) Run(ctx context.Context, logger *zap.Logger, runShard func(context.Context, *Shard) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, len(p.shards))
+	for _, shard := range p.shards {
+		shard := shard
+		go func() {
+			err := runShard(ctx, shard)
+			if err != nil {
+				logger.Error("shard stopped", zap.Int("shard", shard.Index), zap.String("phone", shard.Phone), zap.Error(err))
+			}
+			errCh <- err
+		}()
+	}
+
+	return <-errCh
+}
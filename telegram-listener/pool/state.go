@@ -0,0 +1,124 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gotd/td/telegram/updates"
+)
+
+// RedisStateStorage implements updates.StateStorage backed by Redis, keyed
+// per phone so each shard in the pool tracks its own pts/qts/date/seq and
+// per-channel pts independently and survives a restart without re-fetching
+// the whole update history from scratch.
+type RedisStateStorage struct {
+	rdb   redis.UniversalClient
+	phone string
+}
+
+// NewRedisStateStorage returns a RedisStateStorage for phone.
+func NewRedisStateStorage(rdb redis.UniversalClient, phone string) *RedisStateStorage {
+	return &RedisStateStorage{rdb: rdb, phone: phone}
+}
+
+func (s *RedisStateStorage) stateKey() string {
+	return fmt.Sprintf("telegram:gaps:%s", s.phone)
+}
+
+func (s *RedisStateStorage) channelKey() string {
+	return fmt.Sprintf("telegram:gaps:%s:channels", s.phone)
+}
+
+// GetState implements updates.StateStorage.
+func (s *RedisStateStorage) GetState(ctx context.Context, userID int64) (updates.State, bool, error) {
+	vals, err := s.rdb.HGetAll(ctx, s.stateKey()).Result()
+	if err != nil {
+		return updates.State{}, false, fmt.Errorf("pool: get state for %q: %w", s.phone, err)
+	}
+	if len(vals) == 0 {
+		return updates.State{}, false, nil
+	}
+	return updates.State{
+		Pts:  atoi(vals["pts"]),
+		Qts:  atoi(vals["qts"]),
+		Date: atoi(vals["date"]),
+		Seq:  atoi(vals["seq"]),
+	}, true, nil
+}
+
+// SetState implements updates.StateStorage.
+func (s *RedisStateStorage) SetState(ctx context.Context, userID int64, state updates.State) error {
+	return s.rdb.HSet(ctx, s.stateKey(), map[string]interface{}{
+		"pts":  state.Pts,
+		"qts":  state.Qts,
+		"date": state.Date,
+		"seq":  state.Seq,
+	}).Err()
+}
+
+// SetPts implements updates.StateStorage.
+func (s *RedisStateStorage) SetPts(ctx context.Context, userID int64, pts int) error {
+	return s.rdb.HSet(ctx, s.stateKey(), "pts", pts).Err()
+}
+
+// SetQts implements updates.StateStorage.
+func (s *RedisStateStorage) SetQts(ctx context.Context, userID int64, qts int) error {
+	return s.rdb.HSet(ctx, s.stateKey(), "qts", qts).Err()
+}
+
+// SetDate implements updates.StateStorage.
+func (s *RedisStateStorage) SetDate(ctx context.Context, userID int64, date int) error {
+	return s.rdb.HSet(ctx, s.stateKey(), "date", date).Err()
+}
+
+// SetSeq implements updates.StateStorage.
+func (s *RedisStateStorage) SetSeq(ctx context.Context, userID int64, seq int) error {
+	return s.rdb.HSet(ctx, s.stateKey(), "seq", seq).Err()
+}
+
+// SetDateSeq implements updates.StateStorage.
+func (s *RedisStateStorage) SetDateSeq(ctx context.Context, userID int64, date, seq int) error {
+	return s.rdb.HSet(ctx, s.stateKey(), map[string]interface{}{"date": date, "seq": seq}).Err()
+}
+
+// GetChannelPts implements updates.StateStorage.
+func (s *RedisStateStorage) GetChannelPts(ctx context.Context, userID, channelID int64) (int, bool, error) {
+	val, err := s.rdb.HGet(ctx, s.channelKey(), strconv.FormatInt(channelID, 10)).Result()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("pool: get channel pts for %q/%d: %w", s.phone, channelID, err)
+	}
+	return atoi(val), true, nil
+}
+
+// SetChannelPts implements updates.StateStorage.
+func (s *RedisStateStorage) SetChannelPts(ctx context.Context, userID, channelID int64, pts int) error {
+	return s.rdb.HSet(ctx, s.channelKey(), strconv.FormatInt(channelID, 10), pts).Err()
+}
+
+// ForEachChannels implements updates.StateStorage.
+func (s *RedisStateStorage) ForEachChannels(ctx context.Context, userID int64, f func(ctx context.Context, channelID int64, pts int) error) error {
+	vals, err := s.rdb.HGetAll(ctx, s.channelKey()).Result()
+	if err != nil {
+		return fmt.Errorf("pool: list channels for %q: %w", s.phone, err)
+	}
+	for k, v := range vals {
+		channelID, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			continue
+		}
+		if err := f(ctx, channelID, atoi(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
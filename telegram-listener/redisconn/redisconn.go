@@ -0,0 +1,142 @@
+// Package redisconn builds a redis.UniversalClient from a REDIS_URL, so the
+// listener can point at a single node, a Sentinel deployment, or a cluster
+// without code changes.
+package redisconn
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Config holds everything needed to build a redis.UniversalClient.
+type Config struct {
+	// URL is the connection string. Supported schemes:
+	//   redis://host:port                              single node
+	//   rediss://host:port                              single node, TLS
+	//   redis-sentinel://host1:port1,host2:port2         sentinel
+	//   redis-cluster://host1:port1,host2:port2          cluster
+	URL      string
+	DB       int
+	Password string
+	TLS      bool
+
+	// SentinelMasterName is required when URL uses the redis-sentinel:// scheme.
+	SentinelMasterName string
+
+	// ClusterRouteRandomly enables read load-balancing across cluster replicas.
+	ClusterRouteRandomly bool
+
+	MaxRetries   int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolSize     int
+	MinIdleConns int
+}
+
+// New parses cfg.URL and returns a redis.UniversalClient wired for the
+// requested topology. Callers only ever see the UniversalClient interface,
+// so XAdd/Ping/health-check code works unchanged against single, Sentinel,
+// or cluster deployments.
+func New(cfg Config) (redis.UniversalClient, error) {
+	scheme, rest, err := splitScheme(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+	addrs := splitAddrs(rest)
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("redisconn: no addresses found in %q", cfg.URL)
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLS || scheme == "rediss" {
+		tlsConfig = &tls.Config{}
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:        addrs,
+		DB:           cfg.DB,
+		Password:     cfg.Password,
+		TLSConfig:    tlsConfig,
+		MaxRetries:   cfg.MaxRetries,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		PoolSize:     cfg.PoolSize,
+		MinIdleConns: cfg.MinIdleConns,
+	}
+
+	switch scheme {
+	case "redis", "rediss":
+		if len(addrs) > 1 {
+			return nil, fmt.Errorf("redisconn: scheme %q only supports a single address, got %d", scheme, len(addrs))
+		}
+		return redis.NewUniversalClient(opts), nil
+	case "redis-sentinel":
+		if cfg.SentinelMasterName == "" {
+			return nil, fmt.Errorf("redisconn: SentinelMasterName is required for redis-sentinel:// URLs")
+		}
+		opts.MasterName = cfg.SentinelMasterName
+		return redis.NewUniversalClient(opts), nil
+	case "redis-cluster":
+		// redis.NewUniversalClient only builds a *ClusterClient when given
+		// more than one address, silently falling back to a single-node
+		// *Client (and dropping RouteRandomly) for a one-seed cluster URL.
+		// Build the cluster client directly so a single seed still
+		// discovers the rest of the topology via CLUSTER SLOTS.
+		opts.RouteRandomly = cfg.ClusterRouteRandomly
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("redisconn: unrecognized scheme %q in %q", scheme, cfg.URL)
+	}
+}
+
+// splitScheme separates the URL scheme from the remainder by splitting on
+// the first "://", leaving a comma-separated host list such as
+// "scheme://host1,host2" for splitAddrs to break up.
+func splitScheme(raw string) (scheme, rest string, err error) {
+	idx := strings.Index(raw, "://")
+	if idx < 0 {
+		return "", "", fmt.Errorf("redisconn: %q is missing a scheme", raw)
+	}
+	return raw[:idx], raw[idx+len("://"):], nil
+}
+
+// splitAddrs extracts host:port pairs from the comma-separated remainder of
+// a REDIS_URL, stripping any userinfo (user:pass@) or path/query segments
+// that a single-node URL may still carry.
+func splitAddrs(rest string) []string {
+	var addrs []string
+	for _, part := range strings.Split(rest, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if at := strings.LastIndex(part, "@"); at >= 0 {
+			part = part[at+1:]
+		}
+		if slash := strings.IndexAny(part, "/?"); slash >= 0 {
+			part = part[:slash]
+		}
+		addrs = append(addrs, part)
+	}
+	return addrs
+}
+
+// ParsePassword extracts a password from a single-node REDIS_URL's userinfo
+// (redis://:password@host:port), returning "" if none is present.
+func ParsePassword(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return ""
+	}
+	if pw, ok := u.User.Password(); ok {
+		return pw
+	}
+	return ""
+}
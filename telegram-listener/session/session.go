@@ -0,0 +1,65 @@
+// Package session provides telegram.SessionStorage backends beyond the
+// listener's original file-based one, plus a way to feed a login code in
+// from Redis for deploys with no attached terminal.
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisSessionStorage implements telegram.SessionStorage backed by Redis, so
+// a container can restart or be rescheduled elsewhere without re-running the
+// login flow.
+type RedisSessionStorage struct {
+	rdb redis.UniversalClient
+	key string
+}
+
+// NewRedisSessionStorage returns a RedisSessionStorage that stores phone's
+// session blob under a key namespaced by phone number.
+func NewRedisSessionStorage(rdb redis.UniversalClient, phone string) *RedisSessionStorage {
+	return &RedisSessionStorage{rdb: rdb, key: fmt.Sprintf("telegram:session:%s", phone)}
+}
+
+// LoadSession implements telegram.SessionStorage.
+func (s *RedisSessionStorage) LoadSession(ctx context.Context) ([]byte, error) {
+	data, err := s.rdb.Get(ctx, s.key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("session: load %q: %w", s.key, err)
+	}
+	return data, nil
+}
+
+// StoreSession implements telegram.SessionStorage.
+func (s *RedisSessionStorage) StoreSession(ctx context.Context, data []byte) error {
+	if err := s.rdb.Set(ctx, s.key, data, 0).Err(); err != nil {
+		return fmt.Errorf("session: store %q: %w", s.key, err)
+	}
+	return nil
+}
+
+// FetchAuthCode waits up to timeout for a login code pushed to
+// "auth:code:<phone>" (e.g. via RPUSH from whatever out-of-band channel
+// delivers the SMS/app code), so a headless deploy never needs a stdin
+// prompt. It returns an empty string, nil error on timeout.
+func FetchAuthCode(ctx context.Context, rdb redis.UniversalClient, phone string, timeout time.Duration) (string, error) {
+	key := fmt.Sprintf("auth:code:%s", phone)
+	result, err := rdb.BLPop(ctx, timeout, key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("session: BLPOP %q: %w", key, err)
+	}
+	if len(result) < 2 {
+		return "", fmt.Errorf("session: unexpected BLPOP reply for %q", key)
+	}
+	return result[1], nil
+}
@@ -0,0 +1,88 @@
+// Package streampub publishes detections onto a capped Redis stream with
+// idempotent writes, so a handler retry after a crash or network blip does
+// not produce duplicate downstream trades.
+package streampub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Config controls how the publisher trims the stream and dedupes writes.
+type Config struct {
+	// Stream is the target Redis stream, e.g. "telegram:detections".
+	Stream string
+
+	// MaxLen caps the stream length via XADD MAXLEN. Zero disables trimming.
+	MaxLen int64
+
+	// ApproxTrim uses the "~" approximate trim form, which is much cheaper
+	// than exact trimming and is what production deployments want.
+	ApproxTrim bool
+
+	// DedupeTTL is how long a dedupe key survives. It should comfortably
+	// outlive any retry window for a single message.
+	DedupeTTL time.Duration
+}
+
+// Publisher writes detections to a Redis stream, skipping any write whose
+// dedupe key was already set within the TTL window.
+type Publisher struct {
+	rdb redis.UniversalClient
+	cfg Config
+}
+
+// New returns a Publisher backed by rdb.
+func New(rdb redis.UniversalClient, cfg Config) *Publisher {
+	return &Publisher{rdb: rdb, cfg: cfg}
+}
+
+// Publish dedupes on dedupeKey (SET NX EX) and, if this is the first time
+// the key has been seen, XADDs values to the stream. It returns published
+// = false (with a nil error) when the key was already set, meaning a prior
+// attempt already delivered this payload.
+func (p *Publisher) Publish(ctx context.Context, dedupeKey string, values map[string]interface{}) (published bool, err error) {
+	return p.PublishTo(ctx, p.cfg.Stream, dedupeKey, values)
+}
+
+// PublishTo behaves like Publish but writes to stream instead of the
+// Publisher's configured default, for callers that route detections to a
+// per-chat output stream.
+func (p *Publisher) PublishTo(ctx context.Context, stream, dedupeKey string, values map[string]interface{}) (published bool, err error) {
+	ok, err := p.rdb.SetNX(ctx, dedupeKey, 1, p.cfg.DedupeTTL).Result()
+	if err != nil {
+		return false, fmt.Errorf("streampub: dedupe check for %q: %w", dedupeKey, err)
+	}
+	if !ok {
+		return false, nil
+	}
+
+	args := &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}
+	if p.cfg.MaxLen > 0 {
+		args.MaxLen = p.cfg.MaxLen
+		args.Approx = p.cfg.ApproxTrim
+	}
+	if err := p.rdb.XAdd(ctx, args).Err(); err != nil {
+		// The add never landed, so release the dedupe key now instead of
+		// leaving it set for DedupeTTL — otherwise a retry of this same
+		// detection would be silently dropped as a duplicate, which is
+		// exactly the message loss dedup was meant to prevent, not cause.
+		if delErr := p.rdb.Del(ctx, dedupeKey).Err(); delErr != nil {
+			return false, fmt.Errorf("streampub: xadd to %q: %w (dedupe key %q left set: %v)", stream, err, dedupeKey, delErr)
+		}
+		return false, fmt.Errorf("streampub: xadd to %q: %w", stream, err)
+	}
+	return true, nil
+}
+
+// DedupeKey builds the standard "detection:<chatID>:<messageID>:<contract>"
+// key used to guard against double-publishing the same detection.
+func DedupeKey(chatID int64, messageID int, contract string) string {
+	return fmt.Sprintf("detection:%d:%d:%s", chatID, messageID, contract)
+}
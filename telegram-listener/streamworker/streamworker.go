@@ -0,0 +1,183 @@
+// Package streamworker consumes a Redis stream via a consumer group with
+// at-least-once delivery: XREADGROUP + XACK for the happy path, and
+// XPENDING + XCLAIM to reclaim entries left idle by a crashed consumer.
+// Entries whose delivery count reaches MaxDeliveries are moved to a dead-letter stream
+// instead of being retried forever.
+package streamworker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+// Handler processes a single stream entry. Returning an error leaves the
+// entry pending so it is redelivered via idle reclaim once it has been idle
+// for IdleReclaim.
+type Handler func(ctx context.Context, id string, values map[string]interface{}) error
+
+// Config controls consumer-group membership, redelivery, and the DLQ.
+type Config struct {
+	Stream   string
+	Group    string
+	Consumer string
+
+	// ReadCount and BlockTimeout tune the XREADGROUP call.
+	ReadCount    int64
+	BlockTimeout time.Duration
+
+	// MaxDeliveries is how many times an entry may be delivered (its
+	// XPENDING delivery count, which includes the first read) before it is
+	// moved to DLQStream instead of reclaimed again.
+	MaxDeliveries int64
+
+	// IdleReclaim is the minimum idle time before a pending entry is
+	// eligible for XCLAIM by this (or another) consumer.
+	IdleReclaim time.Duration
+
+	// DLQStream receives the original values of permanently failed entries.
+	DLQStream string
+}
+
+// Worker consumes Config.Stream through Config.Group, invoking Handler for
+// each entry.
+type Worker struct {
+	rdb     redis.UniversalClient
+	cfg     Config
+	handler Handler
+	logger  *zap.Logger
+}
+
+// New returns a Worker. Call EnsureGroup once before Run.
+func New(rdb redis.UniversalClient, cfg Config, handler Handler, logger *zap.Logger) *Worker {
+	return &Worker{rdb: rdb, cfg: cfg, handler: handler, logger: logger}
+}
+
+// EnsureGroup creates the consumer group (and stream, via MKSTREAM) if it
+// does not already exist.
+func (w *Worker) EnsureGroup(ctx context.Context) error {
+	err := w.rdb.XGroupCreateMkStream(ctx, w.cfg.Stream, w.cfg.Group, "0").Err()
+	if err != nil && !errors.Is(err, redis.Nil) && !isBusyGroup(err) {
+		return fmt.Errorf("streamworker: create group %q on %q: %w", w.cfg.Group, w.cfg.Stream, err)
+	}
+	return nil
+}
+
+// Run reads and reclaims entries until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err := w.reclaimIdle(ctx); err != nil {
+			w.logger.Error("idle reclaim failed", zap.String("stream", w.cfg.Stream), zap.Error(err))
+		}
+
+		streams, err := w.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    w.cfg.Group,
+			Consumer: w.cfg.Consumer,
+			Streams:  []string{w.cfg.Stream, ">"},
+			Count:    w.cfg.ReadCount,
+			Block:    w.cfg.BlockTimeout,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("streamworker: xreadgroup on %q: %w", w.cfg.Stream, err)
+		}
+
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				w.process(ctx, msg)
+			}
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, msg redis.XMessage) {
+	if err := w.handler(ctx, msg.ID, msg.Values); err != nil {
+		w.logger.Error("handler failed", zap.String("id", msg.ID), zap.String("stream", w.cfg.Stream), zap.Error(err))
+		return
+	}
+	if err := w.rdb.XAck(ctx, w.cfg.Stream, w.cfg.Group, msg.ID).Err(); err != nil {
+		w.logger.Error("xack failed", zap.String("id", msg.ID), zap.String("stream", w.cfg.Stream), zap.Error(err))
+	}
+}
+
+// reclaimIdle scans pending entries for this group and either claims them
+// back for this consumer (if still under MaxDeliveries) or dead-letters them.
+func (w *Worker) reclaimIdle(ctx context.Context) error {
+	pending, err := w.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: w.cfg.Stream,
+		Group:  w.cfg.Group,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("xpending: %w", err)
+	}
+
+	var toClaim []string
+	for _, p := range pending {
+		if p.Idle < w.cfg.IdleReclaim {
+			continue
+		}
+		if w.cfg.MaxDeliveries > 0 && p.RetryCount >= w.cfg.MaxDeliveries {
+			w.deadLetter(ctx, p.ID)
+			continue
+		}
+		toClaim = append(toClaim, p.ID)
+	}
+	if len(toClaim) == 0 {
+		return nil
+	}
+
+	claimed, err := w.rdb.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   w.cfg.Stream,
+		Group:    w.cfg.Group,
+		Consumer: w.cfg.Consumer,
+		MinIdle:  w.cfg.IdleReclaim,
+		Messages: toClaim,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("xclaim: %w", err)
+	}
+	for _, msg := range claimed {
+		w.process(ctx, msg)
+	}
+	return nil
+}
+
+// deadLetter copies the entry's payload onto DLQStream and acks the
+// original so it stops showing up in XPENDING.
+func (w *Worker) deadLetter(ctx context.Context, id string) {
+	entries, err := w.rdb.XRange(ctx, w.cfg.Stream, id, id).Result()
+	if err != nil || len(entries) == 0 {
+		w.logger.Error("could not read entry for DLQ", zap.String("id", id), zap.String("stream", w.cfg.Stream), zap.Error(err))
+		return
+	}
+	if err := w.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: w.cfg.DLQStream,
+		Values: entries[0].Values,
+	}).Err(); err != nil {
+		w.logger.Error("failed to DLQ entry", zap.String("id", id), zap.Error(err))
+		return
+	}
+	if err := w.rdb.XAck(ctx, w.cfg.Stream, w.cfg.Group, id).Err(); err != nil {
+		w.logger.Error("failed to ack DLQ'd entry", zap.String("id", id), zap.Error(err))
+	}
+}
+
+func isBusyGroup(err error) bool {
+	return err != nil && len(err.Error()) >= 9 && err.Error()[:9] == "BUSYGROUP"
+}
@@ -0,0 +1,170 @@
+package streamworker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+)
+
+func newTestWorker(t *testing.T, cfg Config, handler Handler) (*Worker, redis.UniversalClient, func()) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("miniredis: %v", err)
+	}
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	w := New(rdb, cfg, handler, zap.NewNop())
+	if err := w.EnsureGroup(context.Background()); err != nil {
+		t.Fatalf("EnsureGroup: %v", err)
+	}
+	return w, rdb, func() {
+		rdb.Close()
+		mr.Close()
+	}
+}
+
+func TestWorkerRunProcessesAndAcks(t *testing.T) {
+	cfg := Config{
+		Stream:       "detections",
+		Group:        "traders",
+		Consumer:     "c1",
+		ReadCount:    10,
+		BlockTimeout: 50 * time.Millisecond,
+		DLQStream:    "detections:dlq",
+	}
+
+	var mu sync.Mutex
+	var gotValues map[string]interface{}
+	done := make(chan struct{})
+
+	handler := func(ctx context.Context, id string, values map[string]interface{}) error {
+		mu.Lock()
+		gotValues = values
+		mu.Unlock()
+		close(done)
+		return nil
+	}
+
+	w, rdb, cleanup := newTestWorker(t, cfg, handler)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: cfg.Stream,
+		Values: map[string]interface{}{"address": "0xabc"},
+	}).Err(); err != nil {
+		t.Fatalf("XAdd: %v", err)
+	}
+
+	go w.Run(ctx)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler was never invoked")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if gotValues["address"] != "0xabc" {
+		t.Errorf("handler got values %v, want address=0xabc", gotValues)
+	}
+
+	// The worker sends XACK only after the handler returns, so poll for it
+	// (up to a deadline) before stopping the worker.
+	var pending []redis.XPendingExt
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var err error
+		pending, err = rdb.XPendingExt(context.Background(), &redis.XPendingExtArgs{
+			Stream: cfg.Stream,
+			Group:  cfg.Group,
+			Start:  "-",
+			End:    "+",
+			Count:  100,
+		}).Result()
+		if err != nil {
+			t.Fatalf("XPendingExt: %v", err)
+		}
+		if len(pending) == 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if len(pending) != 0 {
+		t.Errorf("expected entry to be acked, still pending: %v", pending)
+	}
+}
+
+func TestReclaimIdleDeadLettersAfterMaxDeliveries(t *testing.T) {
+	cfg := Config{
+		Stream:        "detections",
+		Group:         "traders",
+		Consumer:      "c1",
+		MaxDeliveries: 1,
+		IdleReclaim:   0,
+		DLQStream:     "detections:dlq",
+	}
+
+	handler := func(ctx context.Context, id string, values map[string]interface{}) error {
+		return nil
+	}
+
+	w, rdb, cleanup := newTestWorker(t, cfg, handler)
+	defer cleanup()
+
+	ctx := context.Background()
+	id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: cfg.Stream,
+		Values: map[string]interface{}{"address": "0xdead"},
+	}).Result()
+	if err != nil {
+		t.Fatalf("XAdd: %v", err)
+	}
+
+	// Read it into the group without acking, so it shows up as pending with
+	// a delivery count of 1 (>= MaxDeliveries), the way a crashed consumer
+	// would leave it.
+	if _, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    cfg.Group,
+		Consumer: "stuck-consumer",
+		Streams:  []string{cfg.Stream, ">"},
+		Count:    1,
+	}).Result(); err != nil {
+		t.Fatalf("XReadGroup: %v", err)
+	}
+
+	if err := w.reclaimIdle(ctx); err != nil {
+		t.Fatalf("reclaimIdle: %v", err)
+	}
+
+	dlq, err := rdb.XRange(ctx, cfg.DLQStream, "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange dlq: %v", err)
+	}
+	if len(dlq) != 1 || dlq[0].Values["address"] != "0xdead" {
+		t.Fatalf("expected entry %s to be dead-lettered, got %v", id, dlq)
+	}
+
+	pending, err := rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: cfg.Stream,
+		Group:  cfg.Group,
+		Start:  "-",
+		End:    "+",
+		Count:  100,
+	}).Result()
+	if err != nil {
+		t.Fatalf("XPendingExt: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected dead-lettered entry to be acked off pending, got %v", pending)
+	}
+}